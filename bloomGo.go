@@ -110,28 +110,7 @@ func (f *BloomFilter) Add(data []byte) *BloomFilter{
 	return f
 }
 
-// Unknown Merge functionality for now
-// func (f *BloomFilter) Merge(g *BloomFilter) error {
-// 	// Make sure the m's and k's are the same, otherwise merging has no real use.
-// 	if f.m != g.m {
-// 		return fmt.Errorf("m's don't match: %d != %d", f.m, g.m)
-// 	}
-
-// 	if f.k != g.k {
-// 		return fmt.Errorf("k's don't match: %d != %d", f.m, g.m)
-// 	}
-
-// 	f.b.InPlaceUnion(g.b)
-// 	return nil
-// }
-
-// Other unknown functionality for now
-// Copy creates a copy of a Bloom filter.
-// func (f *BloomFilter) Copy() *BloomFilter {
-// 	fc := New(f.m, f.k)
-// 	fc.Merge(f) // #nosec
-// 	return fc
-// }
+// Set operations (Union, Intersect, Copy, Equal, UnionOf) live in setops.go.
 
 // // AddString to the Bloom Filter. Returns the filter (allows chaining)
 // func (f *BloomFilter) AddString(data string) *BloomFilter {
@@ -0,0 +1,85 @@
+package bloom
+
+import "sync/atomic"
+
+// ConcurrentBloomFilter is a drop-in alternative to BloomFilter for
+// workloads dominated by concurrent writers. Instead of guarding a
+// bitset.BitSet with a sync.RWMutex, it stores its bits directly in a
+// []uint64 and mutates individual words with atomic compare-and-swap.
+//
+// Because every bit only ever transitions 0->1 and never back, a CAS
+// retry loop can never undo another goroutine's work, and a reader can
+// never observe a bit flip back to unset: Add is safe to call from many
+// goroutines at once, and Verify never produces a false negative for an
+// element that some call to Add has finished setting.
+type ConcurrentBloomFilter struct {
+	numBits   uint
+	numHashes uint
+	words     []uint64
+}
+
+// NewConcurrentBloomFilter constructs a ConcurrentBloomFilter with the
+// given number of bits and hash functions, following the same
+// minimum-of-1 convention as NewBloomFilter.
+func NewConcurrentBloomFilter(numBits, numHashes uint) *ConcurrentBloomFilter {
+	if numBits < 1 {
+		numBits = 1
+	}
+	if numHashes < 1 {
+		numHashes = 1
+	}
+	return &ConcurrentBloomFilter{
+		numBits:   numBits,
+		numHashes: numHashes,
+		words:     make([]uint64, (numBits+63)/64),
+	}
+}
+
+func (f *ConcurrentBloomFilter) location(hashes [4]uint64, i uint) uint {
+	return uint(getLocation(hashes, i) % uint64(f.numBits))
+}
+
+// Add sets the k bits for data, retrying the atomic CAS on each word
+// until it succeeds. Returns f to allow chaining, matching BloomFilter.
+func (f *ConcurrentBloomFilter) Add(data []byte) *ConcurrentBloomFilter {
+	hashes := baseHashes(data)
+	for i := uint(0); i < f.numHashes; i++ {
+		bit := f.location(hashes, i)
+		wordIdx := bit / 64
+		mask := uint64(1) << (bit % 64)
+
+		for {
+			old := atomic.LoadUint64(&f.words[wordIdx])
+			if old&mask != 0 {
+				break // already set by us or another goroutine
+			}
+			if atomic.CompareAndSwapUint64(&f.words[wordIdx], old, old|mask) {
+				break
+			}
+			// Lost the race to another writer touching the same word; retry.
+		}
+	}
+	return f
+}
+
+// Verify checks whether data may be in the filter. As with BloomFilter,
+// false means definitely not present; true may be a false positive.
+func (f *ConcurrentBloomFilter) Verify(data []byte) bool {
+	hashes := baseHashes(data)
+	for i := uint(0); i < f.numHashes; i++ {
+		bit := f.location(hashes, i)
+		word := atomic.LoadUint64(&f.words[bit/64])
+		if word&(uint64(1)<<(bit%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func (f *ConcurrentBloomFilter) NumBits() uint {
+	return f.numBits
+}
+
+func (f *ConcurrentBloomFilter) NumHashes() uint {
+	return f.numHashes
+}
@@ -0,0 +1,100 @@
+package bloom
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+// TestConcurrentBloomFilterAddVerify mirrors TestAddAndVerify for the
+// lock-free variant.
+func TestConcurrentBloomFilterAddVerify(t *testing.T) {
+	bf := NewConcurrentBloomFilter(1000, 7)
+
+	testData := [][]byte{
+		[]byte("hello"),
+		[]byte("world"),
+		[]byte("bloom"),
+	}
+
+	for _, data := range testData {
+		bf.Add(data)
+	}
+
+	for _, data := range testData {
+		if !bf.Verify(data) {
+			t.Errorf("Verify(%s) = false, want true (element was added)", data)
+		}
+	}
+}
+
+// TestConcurrentBloomFilterConcurrentAdd mirrors TestConcurrentAdd: many
+// goroutines Add concurrently and every element must be observable
+// afterwards, with no false negatives.
+func TestConcurrentBloomFilterConcurrentAdd(t *testing.T) {
+	bf := NewConcurrentBloomFilter(10000, 7)
+	var wg sync.WaitGroup
+	numGoroutines := 100
+	itemsPerGoroutine := 100
+
+	for i := 0; i < numGoroutines; i++ {
+		wg.Add(1)
+		go func(id int) {
+			defer wg.Done()
+			for j := 0; j < itemsPerGoroutine; j++ {
+				bf.Add([]byte(fmt.Sprintf("item_%d_%d", id, j)))
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	failures := 0
+	for i := 0; i < numGoroutines; i++ {
+		for j := 0; j < itemsPerGoroutine; j++ {
+			if !bf.Verify([]byte(fmt.Sprintf("item_%d_%d", i, j))) {
+				failures++
+			}
+		}
+	}
+	if failures > 0 {
+		t.Errorf("Failed to verify %d items after concurrent adds", failures)
+	}
+}
+
+// BenchmarkConcurrentBloomFilterAdd compares atomic-CAS Add throughput
+// against the mutex-based BloomFilter across parallelism levels; each
+// level runs parallelism*GOMAXPROCS goroutines.
+func BenchmarkConcurrentBloomFilterAdd(b *testing.B) {
+	for _, parallelism := range []int{1, 4, 16, 64} {
+		b.Run(fmt.Sprintf("parallelism=%d", parallelism), func(b *testing.B) {
+			bf := NewConcurrentBloomFilter(1000000, 7)
+			b.SetParallelism(parallelism)
+			i := int64(0)
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					n := atomic.AddInt64(&i, 1)
+					bf.Add([]byte(fmt.Sprintf("item_%d", n)))
+				}
+			})
+		})
+	}
+}
+
+// BenchmarkMutexBloomFilterAdd is the mutex-based baseline for the
+// comparison in BenchmarkConcurrentBloomFilterAdd.
+func BenchmarkMutexBloomFilterAdd(b *testing.B) {
+	for _, parallelism := range []int{1, 4, 16, 64} {
+		b.Run(fmt.Sprintf("parallelism=%d", parallelism), func(b *testing.B) {
+			bf := NewBloomFilter(1000000, 7)
+			b.SetParallelism(parallelism)
+			i := int64(0)
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					n := atomic.AddInt64(&i, 1)
+					bf.Add([]byte(fmt.Sprintf("item_%d", n)))
+				}
+			})
+		})
+	}
+}
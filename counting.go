@@ -0,0 +1,160 @@
+package bloom
+
+import (
+	"sync"
+)
+
+// CountingBloomFilter is a Bloom filter variant that backs each bit with
+// a small saturating counter instead of a single bit, so elements can be
+// removed again via Remove. Counters are packed counterBits wide into a
+// []uint64; counterBits must be 4 (the default, two counters per byte)
+// or 8 (one counter per byte, doubling memory but allowing more churn
+// before a counter saturates).
+type CountingBloomFilter struct {
+	numBits     uint
+	numHashes   uint
+	counterBits uint
+	maxCount    uint64
+	counters    []uint64
+	mu          sync.RWMutex
+}
+
+// NewCountingBloomFilter constructs a CountingBloomFilter. counterBits
+// must be 4 or 8; any value up to 4 selects 4-bit counters and any
+// larger value selects 8-bit counters.
+func NewCountingBloomFilter(numBits, numHashes, counterBits uint) *CountingBloomFilter {
+	if numBits < 1 {
+		numBits = 1
+	}
+	if numHashes < 1 {
+		numHashes = 1
+	}
+	if counterBits <= 4 {
+		counterBits = 4
+	} else {
+		counterBits = 8
+	}
+
+	countersPerWord := 64 / counterBits
+	numWords := (numBits + countersPerWord - 1) / countersPerWord
+
+	return &CountingBloomFilter{
+		numBits:     numBits,
+		numHashes:   numHashes,
+		counterBits: counterBits,
+		maxCount:    uint64(1)<<counterBits - 1,
+		counters:    make([]uint64, numWords),
+	}
+}
+
+// EstimateCountingParameters behaves like EstimateParameters but also
+// picks a counter width wide enough to tolerate the expected churn:
+// churnRate is the expected number of add/remove cycles per element
+// over the filter's lifetime. A higher churn rate needs wider counters
+// so they don't saturate (and thereby refuse future removals) before
+// the workload settles down.
+func EstimateCountingParameters(dataSize int, fp float64, churnRate float64) (numBits, numHashes, counterBits uint) {
+	numBits, numHashes = EstimateParameters(dataSize, fp)
+
+	expectedMaxCount := (1 + churnRate) * float64(numHashes)
+	if expectedMaxCount > 15 {
+		counterBits = 8
+	} else {
+		counterBits = 4
+	}
+	return
+}
+
+func (f *CountingBloomFilter) countersPerWord() uint {
+	return 64 / f.counterBits
+}
+
+func (f *CountingBloomFilter) getCounter(i uint) uint64 {
+	perWord := f.countersPerWord()
+	word := i / perWord
+	shift := (i % perWord) * f.counterBits
+	return (f.counters[word] >> shift) & f.maxCount
+}
+
+func (f *CountingBloomFilter) setCounter(i uint, v uint64) {
+	perWord := f.countersPerWord()
+	word := i / perWord
+	shift := (i % perWord) * f.counterBits
+	f.counters[word] &^= f.maxCount << shift
+	f.counters[word] |= (v & f.maxCount) << shift
+}
+
+func (f *CountingBloomFilter) location(hashes [4]uint64, i uint) uint {
+	return uint(getLocation(hashes, i) % uint64(f.numBits))
+}
+
+// Add increments the counter at each of the k locations for data,
+// saturating at the counter's max value rather than overflowing.
+func (f *CountingBloomFilter) Add(data []byte) *CountingBloomFilter {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	hashes := baseHashes(data)
+	for i := uint(0); i < f.numHashes; i++ {
+		loc := f.location(hashes, i)
+		if c := f.getCounter(loc); c < f.maxCount {
+			f.setCounter(loc, c+1)
+		}
+	}
+	return f
+}
+
+// Verify checks if the data is in the filter. As with BloomFilter, false
+// means definitely not present; true may be a false positive.
+func (f *CountingBloomFilter) Verify(data []byte) bool {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	hashes := baseHashes(data)
+	for i := uint(0); i < f.numHashes; i++ {
+		if f.getCounter(f.location(hashes, i)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Remove decrements the counter at each of data's k locations, undoing
+// a prior Add. It returns false and leaves the filter unchanged if any
+// of those counters has saturated, since a saturated counter may be
+// shared with other elements whose own counts we can no longer account
+// for; decrementing it could make Verify wrongly return false for one
+// of those other elements.
+func (f *CountingBloomFilter) Remove(data []byte) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	hashes := baseHashes(data)
+	locs := make([]uint, f.numHashes)
+	for i := uint(0); i < f.numHashes; i++ {
+		loc := f.location(hashes, i)
+		if f.getCounter(loc) >= f.maxCount {
+			return false
+		}
+		locs[i] = loc
+	}
+
+	for _, loc := range locs {
+		if c := f.getCounter(loc); c > 0 {
+			f.setCounter(loc, c-1)
+		}
+	}
+	return true
+}
+
+func (f *CountingBloomFilter) NumBits() uint {
+	return f.numBits
+}
+
+func (f *CountingBloomFilter) NumHashes() uint {
+	return f.numHashes
+}
+
+func (f *CountingBloomFilter) CounterBits() uint {
+	return f.counterBits
+}
@@ -0,0 +1,149 @@
+package bloom
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func TestCountingBloomFilterAddVerify(t *testing.T) {
+	cbf := NewCountingBloomFilter(1000, 7, 4)
+
+	testData := [][]byte{
+		[]byte("hello"),
+		[]byte("world"),
+		[]byte("bloom"),
+	}
+
+	for _, data := range testData {
+		cbf.Add(data)
+	}
+
+	for _, data := range testData {
+		if !cbf.Verify(data) {
+			t.Errorf("Verify(%s) = false, want true (element was added)", data)
+		}
+	}
+}
+
+func TestCountingBloomFilterRemove(t *testing.T) {
+	cbf := NewCountingBloomFilter(1000, 7, 4)
+
+	a := []byte("a")
+	b := []byte("b")
+	cbf.Add(a)
+	cbf.Add(b)
+
+	if !cbf.Remove(a) {
+		t.Fatal("Remove() of a present element should succeed")
+	}
+	if cbf.Verify(a) {
+		t.Error("Verify(a) = true after Remove(a), want false")
+	}
+	if !cbf.Verify(b) {
+		t.Error("Verify(b) = false after removing an unrelated element, want true")
+	}
+}
+
+// TestCountingBloomFilterSaturationRefusesRemove checks that once a
+// counter saturates, Remove refuses to touch it rather than risk
+// corrupting another element sharing that slot.
+func TestCountingBloomFilterSaturationRefusesRemove(t *testing.T) {
+	cbf := NewCountingBloomFilter(8, 7, 4) // tiny table, 4-bit counters saturate at 15
+	data := []byte("saturate-me")
+
+	for i := 0; i < 20; i++ {
+		cbf.Add(data)
+	}
+
+	if cbf.Remove(data) {
+		t.Fatal("Remove() should refuse to touch a saturated counter")
+	}
+	if !cbf.Verify(data) {
+		t.Error("a refused Remove() should leave the element verifiable")
+	}
+}
+
+func TestCountingBloomFilterCounterBitsRounding(t *testing.T) {
+	cbf := NewCountingBloomFilter(100, 7, 4)
+	if cbf.CounterBits() != 4 {
+		t.Errorf("CounterBits() = %d, want 4", cbf.CounterBits())
+	}
+
+	cbf8 := NewCountingBloomFilter(100, 7, 8)
+	if cbf8.CounterBits() != 8 {
+		t.Errorf("CounterBits() = %d, want 8", cbf8.CounterBits())
+	}
+
+	cbfSmall := NewCountingBloomFilter(100, 7, 2)
+	if cbfSmall.CounterBits() != 4 {
+		t.Errorf("CounterBits() for requested width 2 = %d, want 4", cbfSmall.CounterBits())
+	}
+
+	cbfLarge := NewCountingBloomFilter(100, 7, 16)
+	if cbfLarge.CounterBits() != 8 {
+		t.Errorf("CounterBits() for requested width 16 = %d, want 8", cbfLarge.CounterBits())
+	}
+}
+
+// TestConcurrentCountingAdd mirrors TestConcurrentAdd for the counting
+// variant.
+func TestConcurrentCountingAdd(t *testing.T) {
+	cbf := NewCountingBloomFilter(10000, 7, 4)
+	var wg sync.WaitGroup
+	numGoroutines := 100
+	itemsPerGoroutine := 100
+
+	for i := 0; i < numGoroutines; i++ {
+		wg.Add(1)
+		go func(id int) {
+			defer wg.Done()
+			for j := 0; j < itemsPerGoroutine; j++ {
+				cbf.Add([]byte(fmt.Sprintf("item_%d_%d", id, j)))
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	failures := 0
+	for i := 0; i < numGoroutines; i++ {
+		for j := 0; j < itemsPerGoroutine; j++ {
+			if !cbf.Verify([]byte(fmt.Sprintf("item_%d_%d", i, j))) {
+				failures++
+			}
+		}
+	}
+	if failures > 0 {
+		t.Errorf("Failed to verify %d items after concurrent adds", failures)
+	}
+}
+
+func TestEstimateCountingParameters(t *testing.T) {
+	numBits, numHashes, counterBits := EstimateCountingParameters(1000, 0.01, 0.1)
+	if numBits == 0 || numHashes == 0 {
+		t.Error("numBits and numHashes should not be 0")
+	}
+	if counterBits != 4 && counterBits != 8 {
+		t.Errorf("counterBits = %d, want 4 or 8", counterBits)
+	}
+
+	_, _, highChurnBits := EstimateCountingParameters(1000, 0.01, 100)
+	if highChurnBits != 8 {
+		t.Errorf("high churn rate should select 8-bit counters, got %d", highChurnBits)
+	}
+}
+
+// BenchmarkCountingBloomFilterAdd compares Add throughput against the
+// plain BloomFilter.
+func BenchmarkCountingBloomFilterAdd(b *testing.B) {
+	cbf := NewCountingBloomFilter(100000, 7, 4)
+	data := make([][]byte, b.N)
+	for i := 0; i < b.N; i++ {
+		data[i] = []byte(fmt.Sprintf("item_%d", i))
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		cbf.Add(data[i])
+	}
+}
@@ -0,0 +1,310 @@
+package bloom
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+	"sync"
+)
+
+const (
+	defaultGrowthFactor     = 2.0
+	defaultTighteningRatio  = 0.8
+	defaultScalableInitCap  = 1000
+	defaultScalableTargetFP = 0.01
+)
+
+// scalableLayer is one generation of a ScalableBloomFilter: a fixed-size
+// BloomFilter sized for capacity elements at the given false positive
+// rate, plus how many elements have been added to it so far.
+type scalableLayer struct {
+	filter   *BloomFilter
+	capacity uint
+	count    uint
+	fp       float64
+}
+
+func newScalableLayer(capacity uint, fp float64) *scalableLayer {
+	numBits, numHashes := EstimateParameters(int(capacity), fp)
+	return &scalableLayer{
+		filter:   NewBloomFilter(numBits, numHashes),
+		capacity: capacity,
+		fp:       fp,
+	}
+}
+
+// ScalableBloomFilter grows on demand to hold more elements than its
+// initial capacity while keeping the compound false positive rate
+// bounded by targetFP. It does this by stacking layers: once the
+// newest layer reaches its capacity, a new layer is appended with
+// growthFactor times more capacity and a tighter (tighteningRatio
+// times smaller) false positive rate, so the sum of all layers' FP
+// rates, which bounds the compound rate, converges to at most targetFP.
+type ScalableBloomFilter struct {
+	mu              sync.RWMutex
+	layers          []*scalableLayer
+	targetFP        float64
+	growthFactor    float64
+	tighteningRatio float64
+}
+
+// ScalableOption configures a ScalableBloomFilter at construction time.
+type ScalableOption func(*ScalableBloomFilter)
+
+// WithGrowthFactor sets how much larger (in capacity) each new layer is
+// than the previous one. The default is 2.
+func WithGrowthFactor(factor float64) ScalableOption {
+	return func(s *ScalableBloomFilter) {
+		s.growthFactor = factor
+	}
+}
+
+// WithTighteningRatio sets how much tighter (smaller) each new layer's
+// false positive rate is than the previous one. The default is 0.8.
+func WithTighteningRatio(ratio float64) ScalableOption {
+	return func(s *ScalableBloomFilter) {
+		s.tighteningRatio = ratio
+	}
+}
+
+// NewScalableBloomFilter constructs a ScalableBloomFilter whose first
+// layer holds initialCapacity elements at the false positive rate chosen
+// by firstLayerFP, so that the compound rate stays within targetFP.
+func NewScalableBloomFilter(initialCapacity int, targetFP float64, opts ...ScalableOption) *ScalableBloomFilter {
+	if initialCapacity < 1 {
+		initialCapacity = defaultScalableInitCap
+	}
+	if targetFP <= 0 {
+		targetFP = defaultScalableTargetFP
+	}
+
+	s := &ScalableBloomFilter{
+		targetFP:        targetFP,
+		growthFactor:    defaultGrowthFactor,
+		tighteningRatio: defaultTighteningRatio,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	s.layers = []*scalableLayer{newScalableLayer(uint(initialCapacity), firstLayerFP(s.targetFP, s.tighteningRatio))}
+	return s
+}
+
+// TargetFP returns the compound false positive rate the filter is
+// aiming to stay under as it grows.
+func (s *ScalableBloomFilter) TargetFP() float64 {
+	return s.targetFP
+}
+
+// firstLayerFP picks the false positive rate for the very first layer
+// such that a geometric series with the given tightening ratio still
+// sums to at most the target compound FP rate.
+func firstLayerFP(targetFP, tighteningRatio float64) float64 {
+	if tighteningRatio <= 0 || tighteningRatio >= 1 {
+		return targetFP
+	}
+	return targetFP * (1 - tighteningRatio)
+}
+
+// Add inserts data, growing the filter with a new layer first if the
+// current newest layer is already at capacity. Returns s for chaining.
+func (s *ScalableBloomFilter) Add(data []byte) *ScalableBloomFilter {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	active := s.layers[len(s.layers)-1]
+	if active.count >= active.capacity {
+		nextCapacity := uint(math.Ceil(float64(active.capacity) * s.growthFactor))
+		nextFP := active.fp * s.tighteningRatio
+		active = newScalableLayer(nextCapacity, nextFP)
+		s.layers = append(s.layers, active)
+	}
+
+	active.filter.Add(data)
+	active.count++
+	return s
+}
+
+// Verify reports whether data may have been added to the filter: true
+// if any layer reports it present, false only if every layer agrees
+// it's definitely absent.
+func (s *ScalableBloomFilter) Verify(data []byte) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, layer := range s.layers {
+		if layer.filter.Verify(data) {
+			return true
+		}
+	}
+	return false
+}
+
+// LayerCount returns how many layers the filter currently has.
+func (s *ScalableBloomFilter) LayerCount() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.layers)
+}
+
+var scalableMagic = [4]byte{'s', 'b', 'l', 'm'}
+
+const scalableFormatVersion uint32 = 1
+
+// maxScalableLayers bounds how many layers ReadFrom will ever allocate
+// for, so a corrupted or malicious stream can't force an enormous
+// allocation via a bogus numLayers before any per-layer data is read.
+// A real ScalableBloomFilter never gets remotely close to this; each
+// layer's capacity grows geometrically, so a few dozen layers already
+// covers cardinalities far beyond what's practical to hold in memory.
+const maxScalableLayers = 1 << 16
+
+var (
+	errBadScalableMagic           = errors.New("bloom: bad magic bytes, not a ScalableBloomFilter")
+	errUnsupportedScalableVersion = errors.New("bloom: unsupported scalable filter format version")
+	errTooManyScalableLayers      = errors.New("bloom: corrupt scalable filter: numLayers exceeds maxScalableLayers")
+	errNoScalableLayers           = errors.New("bloom: corrupt scalable filter: numLayers must be >= 1")
+)
+
+// WriteTo writes a binary representation of the ScalableBloomFilter to
+// w: magic bytes, a uint32 version, the growth factor and tightening
+// ratio, the layer count, and then for each layer its capacity, count,
+// false positive rate, and the layer's own BloomFilter.WriteTo bytes.
+func (s *ScalableBloomFilter) WriteTo(w io.Writer) (int64, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var written int64
+
+	if err := binary.Write(w, binary.BigEndian, scalableMagic); err != nil {
+		return written, err
+	}
+	written += int64(len(scalableMagic))
+
+	if err := binary.Write(w, binary.BigEndian, scalableFormatVersion); err != nil {
+		return written, err
+	}
+	written += 4
+
+	for _, v := range []float64{s.growthFactor, s.tighteningRatio} {
+		if err := binary.Write(w, binary.BigEndian, v); err != nil {
+			return written, err
+		}
+		written += 8
+	}
+
+	if err := binary.Write(w, binary.BigEndian, uint32(len(s.layers))); err != nil {
+		return written, err
+	}
+	written += 4
+
+	for _, layer := range s.layers {
+		if err := binary.Write(w, binary.BigEndian, uint64(layer.capacity)); err != nil {
+			return written, err
+		}
+		written += 8
+
+		if err := binary.Write(w, binary.BigEndian, uint64(layer.count)); err != nil {
+			return written, err
+		}
+		written += 8
+
+		if err := binary.Write(w, binary.BigEndian, layer.fp); err != nil {
+			return written, err
+		}
+		written += 8
+
+		n, err := layer.filter.WriteTo(w)
+		written += n
+		if err != nil {
+			return written, err
+		}
+	}
+
+	return written, nil
+}
+
+// ReadFrom reads a binary representation written by WriteTo into s,
+// replacing its growth factor, tightening ratio and layers. The target
+// false positive rate is not part of the format, so TargetFP keeps the
+// value s had before.
+func (s *ScalableBloomFilter) ReadFrom(r io.Reader) (int64, error) {
+	var read int64
+
+	var magic [4]byte
+	if err := binary.Read(r, binary.BigEndian, &magic); err != nil {
+		return read, err
+	}
+	read += int64(len(magic))
+	if magic != scalableMagic {
+		return read, errBadScalableMagic
+	}
+
+	var version uint32
+	if err := binary.Read(r, binary.BigEndian, &version); err != nil {
+		return read, err
+	}
+	read += 4
+	if version != scalableFormatVersion {
+		return read, errUnsupportedScalableVersion
+	}
+
+	var growthFactor, tighteningRatio float64
+	if err := binary.Read(r, binary.BigEndian, &growthFactor); err != nil {
+		return read, err
+	}
+	read += 8
+	if err := binary.Read(r, binary.BigEndian, &tighteningRatio); err != nil {
+		return read, err
+	}
+	read += 8
+
+	var numLayers uint32
+	if err := binary.Read(r, binary.BigEndian, &numLayers); err != nil {
+		return read, err
+	}
+	read += 4
+	if numLayers == 0 {
+		return read, errNoScalableLayers
+	}
+	if numLayers > maxScalableLayers {
+		return read, errTooManyScalableLayers
+	}
+
+	layers := make([]*scalableLayer, numLayers)
+	for i := range layers {
+		var capacity, count uint64
+		var fp float64
+
+		if err := binary.Read(r, binary.BigEndian, &capacity); err != nil {
+			return read, err
+		}
+		read += 8
+		if err := binary.Read(r, binary.BigEndian, &count); err != nil {
+			return read, err
+		}
+		read += 8
+		if err := binary.Read(r, binary.BigEndian, &fp); err != nil {
+			return read, err
+		}
+		read += 8
+
+		layer := &scalableLayer{filter: &BloomFilter{}, capacity: uint(capacity), count: uint(count), fp: fp}
+		n, err := layer.filter.ReadFrom(r)
+		read += n
+		if err != nil {
+			return read, err
+		}
+		layers[i] = layer
+	}
+
+	s.mu.Lock()
+	s.growthFactor = growthFactor
+	s.tighteningRatio = tighteningRatio
+	s.layers = layers
+	s.mu.Unlock()
+
+	return read, nil
+}
@@ -0,0 +1,92 @@
+package bloom
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+)
+
+func TestScalableBloomFilterGrows(t *testing.T) {
+	sbf := NewScalableBloomFilter(100, 0.01)
+
+	for i := 0; i < 1000; i++ {
+		sbf.Add([]byte(fmt.Sprintf("item_%d", i)))
+	}
+
+	if sbf.LayerCount() < 2 {
+		t.Errorf("LayerCount() = %d, want more than 1 layer after exceeding initial capacity", sbf.LayerCount())
+	}
+
+	for i := 0; i < 1000; i++ {
+		data := []byte(fmt.Sprintf("item_%d", i))
+		if !sbf.Verify(data) {
+			t.Errorf("Verify(%s) = false, want true (element was added)", data)
+		}
+	}
+}
+
+func TestScalableBloomFilterFalsePositiveRate(t *testing.T) {
+	n := 5000
+	p := 0.01
+	sbf := NewScalableBloomFilter(500, p)
+
+	added := make(map[string]bool, n)
+	for i := 0; i < n; i++ {
+		data := []byte(fmt.Sprintf("item_%d", i))
+		sbf.Add(data)
+		added[string(data)] = true
+	}
+
+	falsePositives := 0
+	testSize := 5000
+	for i := 0; i < testSize; i++ {
+		data := []byte(fmt.Sprintf("test_%d", i))
+		if !added[string(data)] && sbf.Verify(data) {
+			falsePositives++
+		}
+	}
+
+	observedFP := float64(falsePositives) / float64(testSize)
+	if observedFP > p*3 {
+		t.Errorf("FP rate too high: %.4f%% (target ~%.4f%%)", observedFP*100, p*100)
+	}
+}
+
+func TestScalableBloomFilterOptions(t *testing.T) {
+	sbf := NewScalableBloomFilter(100, 0.01, WithGrowthFactor(4), WithTighteningRatio(0.5))
+
+	if sbf.growthFactor != 4 {
+		t.Errorf("growthFactor = %v, want 4", sbf.growthFactor)
+	}
+	if sbf.tighteningRatio != 0.5 {
+		t.Errorf("tighteningRatio = %v, want 0.5", sbf.tighteningRatio)
+	}
+}
+
+func TestScalableBloomFilterSerialization(t *testing.T) {
+	sbf := NewScalableBloomFilter(50, 0.01)
+	for i := 0; i < 500; i++ {
+		sbf.Add([]byte(fmt.Sprintf("item_%d", i)))
+	}
+
+	var buf bytes.Buffer
+	if _, err := sbf.WriteTo(&buf); err != nil {
+		t.Fatalf("WriteTo() error: %v", err)
+	}
+
+	var loaded ScalableBloomFilter
+	if _, err := loaded.ReadFrom(&buf); err != nil {
+		t.Fatalf("ReadFrom() error: %v", err)
+	}
+
+	if loaded.LayerCount() != sbf.LayerCount() {
+		t.Errorf("LayerCount() after round-trip = %d, want %d", loaded.LayerCount(), sbf.LayerCount())
+	}
+
+	for i := 0; i < 500; i++ {
+		data := []byte(fmt.Sprintf("item_%d", i))
+		if !loaded.Verify(data) {
+			t.Errorf("Verify(%s) = false after round-trip, want true", data)
+		}
+	}
+}
@@ -0,0 +1,195 @@
+package bloom
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/bits-and-blooms/bitset"
+)
+
+// magicBloom identifies the on-disk format produced by WriteTo so that
+// ReadFrom can reject unrelated data early instead of silently
+// misinterpreting it.
+var magicBloom = [4]byte{'b', 'l', 'o', 'm'}
+
+// binaryFormatVersion is bumped whenever the on-disk layout changes.
+const binaryFormatVersion uint32 = 1
+
+// WriteTo writes a binary representation of the BloomFilter to w. The
+// format is: magic bytes, a uint32 version, numBits and numHashes as
+// uint64s, and finally the bit array using bitset.BitSet's own binary
+// format. It implements io.WriterTo.
+func (f *BloomFilter) WriteTo(w io.Writer) (int64, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	var written int64
+
+	if err := binary.Write(w, binary.BigEndian, magicBloom); err != nil {
+		return written, err
+	}
+	written += int64(len(magicBloom))
+
+	if err := binary.Write(w, binary.BigEndian, binaryFormatVersion); err != nil {
+		return written, err
+	}
+	written += 4
+
+	if err := binary.Write(w, binary.BigEndian, uint64(f.numBits)); err != nil {
+		return written, err
+	}
+	written += 8
+
+	if err := binary.Write(w, binary.BigEndian, uint64(f.numHashes)); err != nil {
+		return written, err
+	}
+	written += 8
+
+	n, err := f.bitset.WriteTo(w)
+	written += n
+	return written, err
+}
+
+// ReadFrom reads a binary representation written by WriteTo into f,
+// replacing its current contents. It implements io.ReaderFrom.
+func (f *BloomFilter) ReadFrom(r io.Reader) (int64, error) {
+	var read int64
+
+	var magic [4]byte
+	if err := binary.Read(r, binary.BigEndian, &magic); err != nil {
+		return read, err
+	}
+	read += int64(len(magic))
+	if magic != magicBloom {
+		return read, fmt.Errorf("bloom: bad magic bytes %v, not a BloomFilter", magic)
+	}
+
+	var version uint32
+	if err := binary.Read(r, binary.BigEndian, &version); err != nil {
+		return read, err
+	}
+	read += 4
+	if version != binaryFormatVersion {
+		return read, fmt.Errorf("bloom: unsupported format version %d", version)
+	}
+
+	var numBits, numHashes uint64
+	if err := binary.Read(r, binary.BigEndian, &numBits); err != nil {
+		return read, err
+	}
+	read += 8
+	if err := binary.Read(r, binary.BigEndian, &numHashes); err != nil {
+		return read, err
+	}
+	read += 8
+	if numBits == 0 || numHashes == 0 {
+		return read, fmt.Errorf("bloom: corrupt filter: numBits=%d numHashes=%d, both must be >= 1", numBits, numHashes)
+	}
+
+	bs := &bitset.BitSet{}
+	n, err := bs.ReadFrom(r)
+	read += n
+	if err != nil {
+		return read, err
+	}
+
+	f.mu.Lock()
+	f.numBits = uint(numBits)
+	f.numHashes = uint(numHashes)
+	f.bitset = bs
+	f.mu.Unlock()
+
+	return read, nil
+}
+
+// Load reads a BloomFilter previously written with WriteTo from r.
+func Load(r io.Reader) (*BloomFilter, error) {
+	f := &BloomFilter{}
+	if _, err := f.ReadFrom(r); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler.
+func (f *BloomFilter) MarshalBinary() ([]byte, error) {
+	var buf bytes.Buffer
+	if _, err := f.WriteTo(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.
+func (f *BloomFilter) UnmarshalBinary(data []byte) error {
+	_, err := f.ReadFrom(bytes.NewReader(data))
+	return err
+}
+
+// GobEncode implements gob.GobEncoder.
+func (f *BloomFilter) GobEncode() ([]byte, error) {
+	return f.MarshalBinary()
+}
+
+// GobDecode implements gob.GobDecoder.
+func (f *BloomFilter) GobDecode(data []byte) error {
+	return f.UnmarshalBinary(data)
+}
+
+// bloomJSON is the wire representation used by MarshalJSON/UnmarshalJSON.
+// The bit array is base64-encoded since JSON has no native binary type.
+type bloomJSON struct {
+	NumBits   uint   `json:"numBits"`
+	NumHashes uint   `json:"numHashes"`
+	Bits      string `json:"bits"`
+}
+
+// MarshalJSON implements json.Marshaler.
+func (f *BloomFilter) MarshalJSON() ([]byte, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	raw, err := f.bitset.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(bloomJSON{
+		NumBits:   f.numBits,
+		NumHashes: f.numHashes,
+		Bits:      base64.StdEncoding.EncodeToString(raw),
+	})
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (f *BloomFilter) UnmarshalJSON(data []byte) error {
+	var wire bloomJSON
+	if err := json.Unmarshal(data, &wire); err != nil {
+		return err
+	}
+	if wire.NumBits == 0 || wire.NumHashes == 0 {
+		return fmt.Errorf("bloom: corrupt filter: numBits=%d numHashes=%d, both must be >= 1", wire.NumBits, wire.NumHashes)
+	}
+
+	raw, err := base64.StdEncoding.DecodeString(wire.Bits)
+	if err != nil {
+		return err
+	}
+
+	bs := &bitset.BitSet{}
+	if err := bs.UnmarshalBinary(raw); err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	f.numBits = wire.NumBits
+	f.numHashes = wire.NumHashes
+	f.bitset = bs
+	f.mu.Unlock()
+
+	return nil
+}
@@ -0,0 +1,92 @@
+package bloom
+
+import (
+	"bytes"
+	"testing"
+)
+
+func populatedFilter() *BloomFilter {
+	bf := NewBloomFilter(10000, 7)
+	for i := 0; i < 200; i++ {
+		bf.Add([]byte{byte(i), byte(i >> 8)})
+	}
+	return bf
+}
+
+func assertSameAnswers(t *testing.T, want, got *BloomFilter) {
+	t.Helper()
+	if want.NumBits() != got.NumBits() || want.NumHashes() != got.NumHashes() {
+		t.Fatalf("params mismatch: want m=%d k=%d, got m=%d k=%d", want.NumBits(), want.NumHashes(), got.NumBits(), got.NumHashes())
+	}
+	for i := 0; i < 300; i++ {
+		data := []byte{byte(i), byte(i >> 8)}
+		if want.Verify(data) != got.Verify(data) {
+			t.Errorf("Verify(%v) mismatch after round-trip", data)
+		}
+	}
+}
+
+// TestWriteToReadFrom tests the binary WriteTo/ReadFrom round-trip.
+func TestWriteToReadFrom(t *testing.T) {
+	bf := populatedFilter()
+
+	var buf bytes.Buffer
+	n, err := bf.WriteTo(&buf)
+	if err != nil {
+		t.Fatalf("WriteTo() error: %v", err)
+	}
+	if n != int64(buf.Len()) {
+		t.Errorf("WriteTo() reported %d bytes, buffer has %d", n, buf.Len())
+	}
+
+	loaded, err := Load(&buf)
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+
+	assertSameAnswers(t, bf, loaded)
+}
+
+// TestReadFromBadMagic tests that ReadFrom rejects data that isn't a
+// BloomFilter.
+func TestReadFromBadMagic(t *testing.T) {
+	buf := bytes.NewReader([]byte("not a bloom filter at all"))
+	var bf BloomFilter
+	if _, err := bf.ReadFrom(buf); err == nil {
+		t.Error("ReadFrom() with bad magic should return an error")
+	}
+}
+
+// TestBinaryMarshaler tests encoding.BinaryMarshaler/Unmarshaler.
+func TestBinaryMarshaler(t *testing.T) {
+	bf := populatedFilter()
+
+	data, err := bf.MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary() error: %v", err)
+	}
+
+	var loaded BloomFilter
+	if err := loaded.UnmarshalBinary(data); err != nil {
+		t.Fatalf("UnmarshalBinary() error: %v", err)
+	}
+
+	assertSameAnswers(t, bf, &loaded)
+}
+
+// TestJSONRoundTrip tests MarshalJSON/UnmarshalJSON.
+func TestJSONRoundTrip(t *testing.T) {
+	bf := populatedFilter()
+
+	data, err := bf.MarshalJSON()
+	if err != nil {
+		t.Fatalf("MarshalJSON() error: %v", err)
+	}
+
+	var loaded BloomFilter
+	if err := loaded.UnmarshalJSON(data); err != nil {
+		t.Fatalf("UnmarshalJSON() error: %v", err)
+	}
+
+	assertSameAnswers(t, bf, &loaded)
+}
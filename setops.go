@@ -0,0 +1,102 @@
+package bloom
+
+import (
+	"errors"
+	"unsafe"
+)
+
+// ErrIncompatibleFilters is returned by the set operations below when the
+// two filters don't share the same numBits/numHashes and therefore can't
+// be combined bit-for-bit.
+var ErrIncompatibleFilters = errors.New("bloom: filters have different numBits/numHashes")
+
+// lockPair locks f and g for writing in a consistent order (by pointer
+// address) so that two goroutines calling e.g. f.Union(g) and g.Union(f)
+// concurrently can never deadlock on each other's locks.
+func lockPair(f, g *BloomFilter) func() {
+	if f == g {
+		f.mu.Lock()
+		return f.mu.Unlock
+	}
+	first, second := f, g
+	if uintptr(unsafe.Pointer(first)) > uintptr(unsafe.Pointer(second)) {
+		first, second = second, first
+	}
+	first.mu.Lock()
+	second.mu.Lock()
+	return func() {
+		second.mu.Unlock()
+		first.mu.Unlock()
+	}
+}
+
+// compatible reports whether f and g have matching parameters and can be
+// combined with a bitwise set operation.
+func (f *BloomFilter) compatible(g *BloomFilter) bool {
+	return f.numBits == g.numBits && f.numHashes == g.numHashes
+}
+
+// Union merges other into f in place, so that afterwards f reports a
+// member as present if either filter reported it as present.
+func (f *BloomFilter) Union(other *BloomFilter) error {
+	unlock := lockPair(f, other)
+	defer unlock()
+
+	if !f.compatible(other) {
+		return ErrIncompatibleFilters
+	}
+	f.bitset.InPlaceUnion(other.bitset)
+	return nil
+}
+
+// Intersect keeps in f only the bits that are also set in other, so that
+// afterwards f reports a member as present only if both filters did
+// (subject to the usual false-positive caveats).
+func (f *BloomFilter) Intersect(other *BloomFilter) error {
+	unlock := lockPair(f, other)
+	defer unlock()
+
+	if !f.compatible(other) {
+		return ErrIncompatibleFilters
+	}
+	f.bitset.InPlaceIntersection(other.bitset)
+	return nil
+}
+
+// Copy returns a deep copy of f.
+func (f *BloomFilter) Copy() *BloomFilter {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	return &BloomFilter{
+		numBits:   f.numBits,
+		numHashes: f.numHashes,
+		bitset:    f.bitset.Clone(),
+	}
+}
+
+// Equal reports whether f and other have the same parameters and the
+// same bits set.
+func (f *BloomFilter) Equal(other *BloomFilter) bool {
+	if f == other {
+		return true
+	}
+	unlock := lockPair(f, other)
+	defer unlock()
+
+	if !f.compatible(other) {
+		return false
+	}
+	return f.bitset.Equal(other.bitset)
+}
+
+// UnionOf returns a new BloomFilter holding the union of a and b,
+// leaving both unmodified. The compatibility check happens inside
+// result.Union(b), under lock, rather than here.
+func UnionOf(a, b *BloomFilter) (*BloomFilter, error) {
+	result := a.Copy()
+	if err := result.Union(b); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
@@ -0,0 +1,131 @@
+package bloom
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func TestUnion(t *testing.T) {
+	a := NewBloomFilter(1000, 7)
+	b := NewBloomFilter(1000, 7)
+
+	a.Add([]byte("a-only"))
+	b.Add([]byte("b-only"))
+
+	if err := a.Union(b); err != nil {
+		t.Fatalf("Union() error: %v", err)
+	}
+
+	if !a.Verify([]byte("a-only")) || !a.Verify([]byte("b-only")) {
+		t.Error("Union() did not merge both filters' members")
+	}
+}
+
+func TestIntersect(t *testing.T) {
+	a := NewBloomFilter(1000, 7)
+	b := NewBloomFilter(1000, 7)
+
+	shared := []byte("shared")
+	a.Add(shared)
+	a.Add([]byte("a-only"))
+	b.Add(shared)
+	b.Add([]byte("b-only"))
+
+	if err := a.Intersect(b); err != nil {
+		t.Fatalf("Intersect() error: %v", err)
+	}
+
+	if !a.Verify(shared) {
+		t.Error("Intersect() dropped a member present in both filters")
+	}
+}
+
+func TestUnionIntersectIncompatible(t *testing.T) {
+	a := NewBloomFilter(1000, 7)
+	b := NewBloomFilter(500, 7)
+
+	if err := a.Union(b); err != ErrIncompatibleFilters {
+		t.Errorf("Union() with mismatched numBits = %v, want ErrIncompatibleFilters", err)
+	}
+	if err := a.Intersect(b); err != ErrIncompatibleFilters {
+		t.Errorf("Intersect() with mismatched numBits = %v, want ErrIncompatibleFilters", err)
+	}
+}
+
+func TestCopy(t *testing.T) {
+	a := NewBloomFilter(1000, 7)
+	a.Add([]byte("hello"))
+
+	b := a.Copy()
+	if !a.Equal(b) {
+		t.Error("Copy() result should be Equal() to the original")
+	}
+
+	b.Add([]byte("world"))
+	if a.Equal(b) {
+		t.Error("mutating the copy should not affect the original")
+	}
+}
+
+func TestEqual(t *testing.T) {
+	a := NewBloomFilter(1000, 7)
+	b := NewBloomFilter(1000, 7)
+
+	if !a.Equal(b) {
+		t.Error("two empty filters with the same params should be Equal")
+	}
+
+	a.Add([]byte("x"))
+	if a.Equal(b) {
+		t.Error("filters with different contents should not be Equal")
+	}
+
+	c := NewBloomFilter(500, 7)
+	if a.Equal(c) {
+		t.Error("filters with different numBits should not be Equal")
+	}
+}
+
+func TestUnionOf(t *testing.T) {
+	a := NewBloomFilter(1000, 7)
+	b := NewBloomFilter(1000, 7)
+	a.Add([]byte("a-only"))
+	b.Add([]byte("b-only"))
+
+	u, err := UnionOf(a, b)
+	if err != nil {
+		t.Fatalf("UnionOf() error: %v", err)
+	}
+
+	if !u.Verify([]byte("a-only")) || !u.Verify([]byte("b-only")) {
+		t.Error("UnionOf() result missing members from either input")
+	}
+	if a.Verify([]byte("b-only")) {
+		t.Error("UnionOf() should not mutate its inputs")
+	}
+}
+
+// TestConcurrentUnionNoDeadlock exercises lockPair's deadlock avoidance
+// by having many goroutines Union() two shared filters in opposite
+// orders at the same time.
+func TestConcurrentUnionNoDeadlock(t *testing.T) {
+	a := NewBloomFilter(10000, 7)
+	b := NewBloomFilter(10000, 7)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			a.Add([]byte(fmt.Sprintf("a_%d", i)))
+			a.Union(b)
+		}(i)
+		go func(i int) {
+			defer wg.Done()
+			b.Add([]byte(fmt.Sprintf("b_%d", i)))
+			b.Union(a)
+		}(i)
+	}
+	wg.Wait()
+}
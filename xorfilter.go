@@ -0,0 +1,339 @@
+package bloom
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+// ErrXorFilterConstruction is returned by BuildXor8 when the peeling
+// algorithm repeatedly stalls even after re-seeding. In practice this
+// should essentially never happen for real inputs; it mostly guards
+// against pathological or duplicate-heavy key sets.
+var ErrXorFilterConstruction = errors.New("bloom: xor filter construction failed to converge")
+
+const xorMaxAttempts = 100
+
+// maxXorBlockLength bounds how large a fingerprint table ReadFrom/
+// UnmarshalJSON will ever allocate for, so a corrupted or malicious
+// stream can't force an oversized allocation via a bogus blockLength
+// before any fingerprint data is read. It corresponds to roughly 163
+// million keys (blockLength*3/1.23), far beyond what BuildXor8 is
+// expected to be asked to construct in practice.
+const maxXorBlockLength = 1 << 26
+
+// XorFilter is a static (build-once) membership structure with a table
+// of roughly 1.23 one-byte slots per element (about 9.84 bits/element)
+// and a ~0.39% false positive rate (the xor8 construction). Unlike BloomFilter, it supports no online inserts or
+// removals after BuildXor8 returns: the whole key set must be known up
+// front. Callers that need to add elements incrementally should keep
+// using BloomFilter or ScalableBloomFilter instead.
+type XorFilter struct {
+	seed         uint64
+	blockLength  uint32
+	fingerprints []uint8
+}
+
+// xorMagic identifies the on-disk format produced by WriteTo.
+var xorMagic = [4]byte{'x', 'o', 'r', '8'}
+
+const xorFormatVersion uint32 = 1
+
+// BuildXor8 constructs a XorFilter containing exactly the given keys.
+// It returns ErrXorFilterConstruction if the peeling algorithm cannot
+// find an assignment after xorMaxAttempts re-seeds.
+func BuildXor8(keys [][]byte) (*XorFilter, error) {
+	n := uint32(len(keys))
+
+	// Each of the 3 segments must be able to hold the keys with room to
+	// spare for peeling to succeed; 1.23*n is the standard overhead
+	// factor for the 3-wise xor construction, rounded up to a multiple
+	// of 3 so the segments are equal length.
+	capacity := uint32(math.Ceil(1.23*float64(n))) + 32
+	blockLength := capacity/3 + 1
+	size := blockLength * 3
+
+	h := make([]uint64, n)
+
+	var seed uint64
+	for attempt := 0; attempt < xorMaxAttempts; attempt++ {
+		seed += 0x9E3779B97F4A7C15 + uint64(attempt)
+
+		for i, key := range keys {
+			h[i] = mixSeed(seed, baseHashes(key)[0])
+		}
+
+		assignedSlot, order, ok := xorPeel(h, blockLength, size)
+		if !ok {
+			continue
+		}
+
+		table := make([]uint8, size)
+		// Walk the peeling order in reverse, assigning each slot a value
+		// such that fingerprint(h) == table[s0] ^ table[s1] ^ table[s2].
+		for i := len(order) - 1; i >= 0; i-- {
+			key := order[i]
+			s0, s1, s2 := xorSegments(h[key], blockLength)
+			slot := assignedSlot[key]
+			var other1, other2 uint32
+			switch slot {
+			case s0:
+				other1, other2 = s1, s2
+			case s1:
+				other1, other2 = s0, s2
+			default:
+				other1, other2 = s0, s1
+			}
+			table[slot] = xorFingerprint(h[key]) ^ table[other1] ^ table[other2]
+		}
+
+		return &XorFilter{seed: seed, blockLength: blockLength, fingerprints: table}, nil
+	}
+
+	return nil, ErrXorFilterConstruction
+}
+
+// xorPeel runs the 3-wise peeling algorithm over the precomputed hashes
+// h. It returns, for every key index, the slot it was finally assigned
+// to, and the order keys were peeled in (needed to replay assignment in
+// reverse). ok is false if peeling stalled before every key was placed.
+func xorPeel(h []uint64, blockLength, size uint32) (assignedSlot []uint32, order []int, ok bool) {
+	occupancy := make([]uint32, size)
+	xorkey := make([]int, size) // XOR of key indices touching this slot; equals the sole key's index while occupancy[s] == 1
+
+	for key, hv := range h {
+		s0, s1, s2 := xorSegments(hv, blockLength)
+		for _, s := range [3]uint32{s0, s1, s2} {
+			occupancy[s]++
+			xorkey[s] ^= key
+		}
+	}
+
+	queue := make([]uint32, 0, len(h))
+	for s := uint32(0); s < size; s++ {
+		if occupancy[s] == 1 {
+			queue = append(queue, s)
+		}
+	}
+
+	assignedSlot = make([]uint32, len(h))
+	peeled := make([]bool, len(h))
+
+	for len(queue) > 0 {
+		s := queue[len(queue)-1]
+		queue = queue[:len(queue)-1]
+		if occupancy[s] != 1 {
+			continue // stale queue entry from an already-peeled slot
+		}
+
+		key := xorkey[s]
+		if peeled[key] {
+			continue
+		}
+		peeled[key] = true
+		assignedSlot[key] = s
+		order = append(order, key)
+
+		s0, s1, s2 := xorSegments(h[key], blockLength)
+		for _, other := range [3]uint32{s0, s1, s2} {
+			if other == s {
+				continue
+			}
+			occupancy[other]--
+			xorkey[other] ^= key
+			if occupancy[other] == 1 {
+				queue = append(queue, other)
+			}
+		}
+	}
+
+	return assignedSlot, order, len(order) == len(h)
+}
+
+// xorSegments splits a key's combined hash into 3 slot indices, one in
+// each of 3 disjoint segments of the table.
+func xorSegments(h uint64, blockLength uint32) (s0, s1, s2 uint32) {
+	s0 = uint32(h%uint64(blockLength)) + 0*blockLength
+	s1 = uint32((h>>21)%uint64(blockLength)) + 1*blockLength
+	s2 = uint32((h>>42)%uint64(blockLength)) + 2*blockLength
+	return
+}
+
+// xorFingerprint derives the 8-bit fingerprint stored for a key from its
+// combined hash.
+func xorFingerprint(h uint64) uint8 {
+	return uint8(h ^ (h >> 32))
+}
+
+// mixSeed combines a construction seed with a key's base hash so that a
+// failed peeling attempt can be retried with an independent hash
+// assignment without re-hashing the original key data.
+func mixSeed(seed, h uint64) uint64 {
+	x := h ^ seed
+	x ^= x >> 33
+	x *= 0xff51afd7ed558ccd
+	x ^= x >> 33
+	x *= 0xc4ceb9fe1a85ec53
+	x ^= x >> 33
+	return x
+}
+
+// Contains reports whether data may have been added to the filter. As
+// with BloomFilter, false means definitely not present; true may be a
+// (roughly 0.39%) false positive. Contains is safe for concurrent use
+// since a XorFilter is never mutated after BuildXor8 returns.
+func (x *XorFilter) Contains(data []byte) bool {
+	h := mixSeed(x.seed, baseHashes(data)[0])
+	s0, s1, s2 := xorSegments(h, x.blockLength)
+	return xorFingerprint(h) == x.fingerprints[s0]^x.fingerprints[s1]^x.fingerprints[s2]
+}
+
+// WriteTo writes a binary representation of the XorFilter to w: magic
+// bytes, a uint32 version, the seed and blockLength, then the raw
+// fingerprint bytes.
+func (x *XorFilter) WriteTo(w io.Writer) (int64, error) {
+	var written int64
+
+	if err := binary.Write(w, binary.BigEndian, xorMagic); err != nil {
+		return written, err
+	}
+	written += int64(len(xorMagic))
+
+	if err := binary.Write(w, binary.BigEndian, xorFormatVersion); err != nil {
+		return written, err
+	}
+	written += 4
+
+	if err := binary.Write(w, binary.BigEndian, x.seed); err != nil {
+		return written, err
+	}
+	written += 8
+
+	if err := binary.Write(w, binary.BigEndian, x.blockLength); err != nil {
+		return written, err
+	}
+	written += 4
+
+	n, err := w.Write(x.fingerprints)
+	written += int64(n)
+	return written, err
+}
+
+// ReadFrom reads a binary representation written by WriteTo into x,
+// replacing its current contents.
+func (x *XorFilter) ReadFrom(r io.Reader) (int64, error) {
+	var read int64
+
+	var magic [4]byte
+	if err := binary.Read(r, binary.BigEndian, &magic); err != nil {
+		return read, err
+	}
+	read += int64(len(magic))
+	if magic != xorMagic {
+		return read, errors.New("bloom: bad magic bytes, not a XorFilter")
+	}
+
+	var version uint32
+	if err := binary.Read(r, binary.BigEndian, &version); err != nil {
+		return read, err
+	}
+	read += 4
+	if version != xorFormatVersion {
+		return read, errors.New("bloom: unsupported xor filter format version")
+	}
+
+	var seed uint64
+	if err := binary.Read(r, binary.BigEndian, &seed); err != nil {
+		return read, err
+	}
+	read += 8
+
+	var blockLength uint32
+	if err := binary.Read(r, binary.BigEndian, &blockLength); err != nil {
+		return read, err
+	}
+	read += 4
+	if blockLength == 0 || blockLength > maxXorBlockLength {
+		return read, fmt.Errorf("bloom: corrupt xor filter: blockLength %d out of range [1, %d]", blockLength, maxXorBlockLength)
+	}
+
+	fingerprints := make([]uint8, blockLength*3)
+	n, err := io.ReadFull(r, fingerprints)
+	read += int64(n)
+	if err != nil {
+		return read, err
+	}
+
+	x.seed = seed
+	x.blockLength = blockLength
+	x.fingerprints = fingerprints
+	return read, nil
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler.
+func (x *XorFilter) MarshalBinary() ([]byte, error) {
+	var buf bytes.Buffer
+	if _, err := x.WriteTo(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.
+func (x *XorFilter) UnmarshalBinary(data []byte) error {
+	_, err := x.ReadFrom(bytes.NewReader(data))
+	return err
+}
+
+// GobEncode implements gob.GobEncoder.
+func (x *XorFilter) GobEncode() ([]byte, error) {
+	return x.MarshalBinary()
+}
+
+// GobDecode implements gob.GobDecoder.
+func (x *XorFilter) GobDecode(data []byte) error {
+	return x.UnmarshalBinary(data)
+}
+
+// xorFilterJSON is the wire representation used by MarshalJSON/UnmarshalJSON.
+type xorFilterJSON struct {
+	Seed         uint64 `json:"seed"`
+	BlockLength  uint32 `json:"blockLength"`
+	Fingerprints string `json:"fingerprints"`
+}
+
+// MarshalJSON implements json.Marshaler.
+func (x *XorFilter) MarshalJSON() ([]byte, error) {
+	return json.Marshal(xorFilterJSON{
+		Seed:         x.seed,
+		BlockLength:  x.blockLength,
+		Fingerprints: base64.StdEncoding.EncodeToString(x.fingerprints),
+	})
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (x *XorFilter) UnmarshalJSON(data []byte) error {
+	var wire xorFilterJSON
+	if err := json.Unmarshal(data, &wire); err != nil {
+		return err
+	}
+	fingerprints, err := base64.StdEncoding.DecodeString(wire.Fingerprints)
+	if err != nil {
+		return err
+	}
+	if wire.BlockLength == 0 || wire.BlockLength > maxXorBlockLength {
+		return fmt.Errorf("bloom: corrupt xor filter: blockLength %d out of range [1, %d]", wire.BlockLength, maxXorBlockLength)
+	}
+	if uint32(len(fingerprints)) != wire.BlockLength*3 {
+		return errors.New("bloom: corrupt xor filter: fingerprints length does not match blockLength")
+	}
+	x.seed = wire.Seed
+	x.blockLength = wire.BlockLength
+	x.fingerprints = fingerprints
+	return nil
+}
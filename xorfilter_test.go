@@ -0,0 +1,106 @@
+package bloom
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+)
+
+func sampleKeys(n int) [][]byte {
+	keys := make([][]byte, n)
+	for i := range keys {
+		keys[i] = []byte(fmt.Sprintf("xor_key_%d", i))
+	}
+	return keys
+}
+
+// TestBuildXor8Contains verifies every inserted key is reported present.
+func TestBuildXor8Contains(t *testing.T) {
+	keys := sampleKeys(2000)
+
+	xf, err := BuildXor8(keys)
+	if err != nil {
+		t.Fatalf("BuildXor8() error: %v", err)
+	}
+
+	for _, key := range keys {
+		if !xf.Contains(key) {
+			t.Fatalf("Contains(%s) = false, want true (key was built in)", key)
+		}
+	}
+}
+
+// TestBuildXor8FalsePositiveRate checks that the observed FP rate is in
+// the right ballpark for the xor8 construction (~0.39% in theory).
+func TestBuildXor8FalsePositiveRate(t *testing.T) {
+	keys := sampleKeys(20000)
+	xf, err := BuildXor8(keys)
+	if err != nil {
+		t.Fatalf("BuildXor8() error: %v", err)
+	}
+
+	falsePositives := 0
+	testSize := 20000
+	for i := 0; i < testSize; i++ {
+		data := []byte(fmt.Sprintf("not_a_key_%d", i))
+		if xf.Contains(data) {
+			falsePositives++
+		}
+	}
+
+	observedFP := float64(falsePositives) / float64(testSize)
+	if observedFP > 0.02 {
+		t.Errorf("observed FP rate too high: %.4f%% (expected ~0.39%%)", observedFP*100)
+	}
+}
+
+// TestXorFilterSerialization round-trips a XorFilter through its binary
+// serialization hooks.
+func TestXorFilterSerialization(t *testing.T) {
+	keys := sampleKeys(500)
+	xf, err := BuildXor8(keys)
+	if err != nil {
+		t.Fatalf("BuildXor8() error: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if _, err := xf.WriteTo(&buf); err != nil {
+		t.Fatalf("WriteTo() error: %v", err)
+	}
+
+	var loaded XorFilter
+	if _, err := loaded.ReadFrom(&buf); err != nil {
+		t.Fatalf("ReadFrom() error: %v", err)
+	}
+
+	for _, key := range keys {
+		if !loaded.Contains(key) {
+			t.Errorf("Contains(%s) = false after round-trip, want true", key)
+		}
+	}
+}
+
+// TestXorFilterJSONRoundTrip exercises MarshalJSON/UnmarshalJSON.
+func TestXorFilterJSONRoundTrip(t *testing.T) {
+	keys := sampleKeys(500)
+	xf, err := BuildXor8(keys)
+	if err != nil {
+		t.Fatalf("BuildXor8() error: %v", err)
+	}
+
+	data, err := xf.MarshalJSON()
+	if err != nil {
+		t.Fatalf("MarshalJSON() error: %v", err)
+	}
+
+	var loaded XorFilter
+	if err := loaded.UnmarshalJSON(data); err != nil {
+		t.Fatalf("UnmarshalJSON() error: %v", err)
+	}
+
+	for _, key := range keys {
+		if !loaded.Contains(key) {
+			t.Errorf("Contains(%s) = false after JSON round-trip, want true", key)
+		}
+	}
+}